@@ -0,0 +1,63 @@
+// ATT&CK Enterprise 技术映射表，供分类打标（classify.go）与导出（export 包）共用
+package attackchain
+
+import "fmt"
+
+// Technique 是内置 MITRE ATT&CK Enterprise 映射表中的一条技术条目，
+// 仅用于导出前的合法性校验，不追求覆盖官方矩阵的全部技术
+type Technique struct {
+	ID     string // 例如 T1595.001
+	Name   string
+	Tactic string // 例如 reconnaissance
+}
+
+// enterpriseMapping 内置的 ATT&CK Enterprise 技术子集，用于导出前拒绝未知 ID。
+// 完整矩阵体积较大，这里只维护攻击链常见阶段覆盖到的技术，后续可按需补充。
+var enterpriseMapping = map[string]Technique{
+	"T1595.001": {ID: "T1595.001", Name: "Scanning IP Blocks", Tactic: TacticReconnaissance},
+	"T1595.002": {ID: "T1595.002", Name: "Vulnerability Scanning", Tactic: TacticReconnaissance},
+	"T1590":     {ID: "T1590", Name: "Gather Victim Network Information", Tactic: TacticReconnaissance},
+	"T1587.001": {ID: "T1587.001", Name: "Develop Capabilities: Malware", Tactic: TacticResourceDevelopment},
+	"T1588.002": {ID: "T1588.002", Name: "Obtain Capabilities: Tool", Tactic: TacticResourceDevelopment},
+	"T1190":     {ID: "T1190", Name: "Exploit Public-Facing Application", Tactic: TacticInitialAccess},
+	"T1078":     {ID: "T1078", Name: "Valid Accounts", Tactic: TacticInitialAccess},
+	"T1059":     {ID: "T1059", Name: "Command and Scripting Interpreter", Tactic: TacticExecution},
+	"T1053.005": {ID: "T1053.005", Name: "Scheduled Task", Tactic: TacticPersistence},
+	"T1068":     {ID: "T1068", Name: "Exploitation for Privilege Escalation", Tactic: TacticPrivilegeEscalation},
+	"T1070":     {ID: "T1070", Name: "Indicator Removal", Tactic: TacticDefenseEvasion},
+	"T1110":     {ID: "T1110", Name: "Brute Force", Tactic: TacticCredentialAccess},
+	"T1046":     {ID: "T1046", Name: "Network Service Discovery", Tactic: TacticDiscovery},
+	"T1021":     {ID: "T1021", Name: "Remote Services", Tactic: TacticLateralMovement},
+	"T1560":     {ID: "T1560", Name: "Archive Collected Data", Tactic: TacticCollection},
+	"T1071":     {ID: "T1071", Name: "Application Layer Protocol", Tactic: TacticCommandAndControl},
+	"T1041":     {ID: "T1041", Name: "Exfiltration Over C2 Channel", Tactic: TacticExfiltration},
+	"T1486":     {ID: "T1486", Name: "Data Encrypted for Impact", Tactic: TacticImpact},
+}
+
+// ATT&CK Enterprise 战术（tactic）标识，与 navigator 层使用的命名保持一致
+const (
+	TacticReconnaissance      = "reconnaissance"
+	TacticResourceDevelopment = "resource-development"
+	TacticInitialAccess       = "initial-access"
+	TacticExecution           = "execution"
+	TacticPersistence         = "persistence"
+	TacticPrivilegeEscalation = "privilege-escalation"
+	TacticDefenseEvasion      = "defense-evasion"
+	TacticCredentialAccess    = "credential-access"
+	TacticDiscovery           = "discovery"
+	TacticLateralMovement     = "lateral-movement"
+	TacticCollection          = "collection"
+	TacticCommandAndControl   = "command-and-control"
+	TacticExfiltration        = "exfiltration"
+	TacticImpact              = "impact"
+)
+
+// ValidateTechnique 校验一个 ATT&CK technique ID 是否存在于内置映射表中，
+// 导出前必须通过该校验，未知 ID 会被直接拒绝而不是静默放行
+func ValidateTechnique(id string) (Technique, error) {
+	t, ok := enterpriseMapping[id]
+	if !ok {
+		return Technique{}, fmt.Errorf("未知的 ATT&CK technique ID: %s", id)
+	}
+	return t, nil
+}
@@ -0,0 +1,88 @@
+package attackchain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// classificationResponse 是分类请求要求模型返回的 JSON 结构
+type classificationResponse struct {
+	TechniqueID string `json:"technique_id"`
+	Description string `json:"description,omitempty"`
+}
+
+// classificationPrompt 构造一次分类请求的消息列表，要求模型把单条操作标注为
+// enterpriseMapping 中已知的 ATT&CK technique，而不是任意编造的 ID
+func (b *Builder) classificationPrompt(action ExtractedAction) []openai.ChatCompletionMessage {
+	system := "你是一名 MITRE ATT&CK 专家，需要把安全测试过程中的单条操作标注为最贴切的 " +
+		"ATT&CK Enterprise technique。只能从下面列出的 technique ID 中选择，严禁编造不在列表中的 ID；" +
+		"如果实在无法判断，选择列表中语义上最接近的一项。只返回一个 JSON 对象，不要输出任何其他内容，格式为：\n" +
+		`{"technique_id": "T1059", "description": "对该操作的一句话说明"}` + "\n\n可选 technique：\n" + techniqueCatalog()
+
+	return []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: system},
+		{
+			Role: openai.ChatMessageRoleUser,
+			Content: fmt.Sprintf("工具: %s\n目标: %s\n操作: %s", action.Tool, action.Target, action.Description),
+		},
+	}
+}
+
+// parseClassification 解析 LLM 返回的分类结果，并按 enterpriseMapping 校验 technique_id，
+// 未知 ID 直接报错而不是生成一个没有 Tactic/TechniqueID 的“哑”节点
+func (b *Builder) parseClassification(action ExtractedAction, content string) (ChainNode, error) {
+	var resp classificationResponse
+	if err := json.Unmarshal([]byte(extractJSONObject(content)), &resp); err != nil {
+		return ChainNode{}, fmt.Errorf("解析分类结果 JSON 失败: %w（原始内容: %s）", err, content)
+	}
+
+	tech, err := ValidateTechnique(resp.TechniqueID)
+	if err != nil {
+		return ChainNode{}, fmt.Errorf("分类结果包含未知的 ATT&CK technique: %w", err)
+	}
+
+	description := resp.Description
+	if description == "" {
+		description = action.Description
+	}
+
+	return ChainNode{
+		ID:          action.ID,
+		Description: description,
+		Tool:        action.Tool,
+		Target:      action.Target,
+		Tactic:      tech.Tactic,
+		TechniqueID: tech.ID,
+	}, nil
+}
+
+// techniqueCatalog 把内置映射表渲染为供 LLM 选择的列表，按 ID 排序保证提示词稳定
+func techniqueCatalog() string {
+	ids := make([]string, 0, len(enterpriseMapping))
+	for id := range enterpriseMapping {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var sb strings.Builder
+	for _, id := range ids {
+		t := enterpriseMapping[id]
+		sb.WriteString(fmt.Sprintf("- %s (%s): %s\n", t.ID, t.Tactic, t.Name))
+	}
+	return sb.String()
+}
+
+// extractJSONObject 从模型输出中截取第一个 "{" 到最后一个 "}" 之间的内容，容忍模型在 JSON 前后
+// 附带的 ```json 代码块标记或多余说明文字；输出中含多个对象时不会只取第一个
+func extractJSONObject(content string) string {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start == -1 || end == -1 || end < start {
+		return content
+	}
+	return content[start : end+1]
+}
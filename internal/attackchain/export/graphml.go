@@ -0,0 +1,97 @@
+package export
+
+import (
+	"encoding/xml"
+
+	"cyberstrike-ai/internal/attackchain"
+)
+
+// GraphML 的最小结构定义（graph/node/edge + 一组 data 键），
+// 足以被 yEd、Gephi 等通用图工具读取
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// ToGraphML 渲染为 GraphML XML，节点携带 description/tactic/technique 三个属性。
+// 与 ToSTIX/ToNavigatorLayer 保持同样的校验口径：未知 technique ID 直接报错，而不是
+// 静默写出一份 yEd/Gephi 能打开、但标注全部错误的图。
+func ToGraphML(chain *attackchain.Chain) (*graphMLDocument, error) {
+	doc := &graphMLDocument{
+		XMLName: xml.Name{Local: "graphml"},
+		Keys: []graphMLKey{
+			{ID: "d_desc", For: "node", AttrName: "description", AttrType: "string"},
+			{ID: "d_tactic", For: "node", AttrName: "tactic", AttrType: "string"},
+			{ID: "d_technique", For: "node", AttrName: "technique", AttrType: "string"},
+		},
+		Graph: graphMLGraph{
+			ID:          chain.ConversationID,
+			EdgeDefault: "directed",
+		},
+	}
+
+	// exported 记录哪些节点实际进了图里，跳过的（未分类）节点不能再被 edge 引用，
+	// 否则产出的 GraphML 里会有指向未声明节点的悬空边，yEd/Gephi 都解析不了
+	exported := make(map[string]struct{}, len(chain.Nodes))
+
+	for _, node := range chain.Nodes {
+		if node.TechniqueID == "" {
+			continue
+		}
+		if _, err := attackchain.ValidateTechnique(node.TechniqueID); err != nil {
+			return nil, err
+		}
+
+		exported[node.ID] = struct{}{}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			ID: node.ID,
+			Data: []graphMLData{
+				{Key: "d_desc", Value: node.Description},
+				{Key: "d_tactic", Value: node.Tactic},
+				{Key: "d_technique", Value: node.TechniqueID},
+			},
+		})
+	}
+
+	for _, edge := range chain.Edges {
+		if _, ok := exported[edge.From]; !ok {
+			continue
+		}
+		if _, ok := exported[edge.To]; !ok {
+			continue
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: edge.From, Target: edge.To})
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,43 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"cyberstrike-ai/internal/attackchain"
+)
+
+// ToMermaid 渲染为 Mermaid flowchart 源码，用于在支持 Mermaid 的文档/Wiki 中直接嵌入攻击链图
+func ToMermaid(chain *attackchain.Chain) string {
+	var b strings.Builder
+	b.WriteString("flowchart TD\n")
+
+	for _, node := range chain.Nodes {
+		label := node.Description
+		if node.TechniqueID != "" {
+			label = fmt.Sprintf("%s\\n[%s]", label, node.TechniqueID)
+		}
+		b.WriteString(fmt.Sprintf("    %s[%q]\n", mermaidNodeID(node.ID), label))
+	}
+
+	for _, edge := range chain.Edges {
+		b.WriteString(fmt.Sprintf("    %s --> %s\n", mermaidNodeID(edge.From), mermaidNodeID(edge.To)))
+	}
+
+	return b.String()
+}
+
+// mermaidNodeID 把任意节点 ID 转成 Mermaid 允许的标识符（仅字母数字与下划线）
+func mermaidNodeID(id string) string {
+	var b strings.Builder
+	b.WriteString("n")
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
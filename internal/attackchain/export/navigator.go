@@ -0,0 +1,80 @@
+package export
+
+import "cyberstrike-ai/internal/attackchain"
+
+// NavigatorLayer 是 ATT&CK Navigator layer v4.5 格式的最小子集，
+// 足以在 navigator.mitre.org 导入并渲染热力图
+type NavigatorLayer struct {
+	Name        string               `json:"name"`
+	Versions    NavigatorVersions    `json:"versions"`
+	Domain      string               `json:"domain"`
+	Description string               `json:"description,omitempty"`
+	Techniques  []NavigatorTechnique `json:"techniques"`
+	Gradient    NavigatorGradient    `json:"gradient"`
+}
+
+type NavigatorVersions struct {
+	Layer     string `json:"layer"`
+	Navigator string `json:"navigator"`
+	ATTACK    string `json:"attack"`
+}
+
+type NavigatorTechnique struct {
+	TechniqueID string `json:"techniqueID"`
+	Tactic      string `json:"tactic"`
+	Score       int    `json:"score"`
+	Comment     string `json:"comment,omitempty"`
+	Enabled     bool   `json:"enabled"`
+}
+
+type NavigatorGradient struct {
+	Colors   []string `json:"colors"`
+	MinValue int      `json:"minValue"`
+	MaxValue int      `json:"maxValue"`
+}
+
+// ToNavigatorLayer 复用攻击链节点上已经打好的 ATT&CK 标签，生成一个按出现次数
+// 计分的 Navigator 热力图层；未知技术会被拒绝，与 STIX 导出保持同样的校验口径
+func ToNavigatorLayer(chain *attackchain.Chain, name string) (*NavigatorLayer, error) {
+	counts := make(map[string]int)
+	tactics := make(map[string]string)
+	order := make([]string, 0)
+
+	for _, node := range chain.Nodes {
+		if node.TechniqueID == "" {
+			continue
+		}
+		tech, err := attackchain.ValidateTechnique(node.TechniqueID)
+		if err != nil {
+			return nil, err
+		}
+		if counts[node.TechniqueID] == 0 {
+			order = append(order, node.TechniqueID)
+		}
+		counts[node.TechniqueID]++
+		tactics[node.TechniqueID] = tech.Tactic
+	}
+
+	layer := &NavigatorLayer{
+		Name:        name,
+		Domain:      "enterprise-attack",
+		Description: "Generated by CyberStrikeAI from conversation " + chain.ConversationID,
+		Versions:    NavigatorVersions{Layer: "4.5", Navigator: "4.9.1", ATTACK: "14"},
+		Gradient: NavigatorGradient{
+			Colors:   []string{"#ffffff", "#ff6666"},
+			MinValue: 0,
+			MaxValue: len(order),
+		},
+	}
+
+	for _, id := range order {
+		layer.Techniques = append(layer.Techniques, NavigatorTechnique{
+			TechniqueID: id,
+			Tactic:      tactics[id],
+			Score:       counts[id],
+			Enabled:     true,
+		})
+	}
+
+	return layer, nil
+}
@@ -0,0 +1,166 @@
+package export
+
+import (
+	"fmt"
+	"time"
+
+	"cyberstrike-ai/internal/attackchain"
+
+	"github.com/google/uuid"
+)
+
+// STIX 2.1 bundle/SDO/SRO 的最小字段集合，足以满足攻击链导出场景，
+// 没有照搬完整 STIX 对象模型（大量可选属性本场景用不到）
+type StixBundle struct {
+	Type    string        `json:"type"`
+	ID      string        `json:"id"`
+	Objects []interface{} `json:"objects"`
+}
+
+type stixCommon struct {
+	Type     string `json:"type"`
+	ID       string `json:"id"`
+	Created  string `json:"created"`
+	Modified string `json:"modified"`
+}
+
+type StixAttackPattern struct {
+	stixCommon
+	Name               string               `json:"name"`
+	Description        string               `json:"description,omitempty"`
+	ExternalReferences []StixExternalRef    `json:"external_references"`
+	KillChainPhases    []StixKillChainPhase `json:"kill_chain_phases,omitempty"`
+}
+
+type StixExternalRef struct {
+	SourceName string `json:"source_name"`
+	ExternalID string `json:"external_id"`
+	URL        string `json:"url,omitempty"`
+}
+
+type StixKillChainPhase struct {
+	KillChainName string `json:"kill_chain_name"`
+	PhaseName     string `json:"phase_name"`
+}
+
+type StixTool struct {
+	stixCommon
+	Name        string   `json:"name"`
+	ToolTypes   []string `json:"tool_types,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+type StixInfrastructure struct {
+	stixCommon
+	Name                string   `json:"name"`
+	InfrastructureTypes []string `json:"infrastructure_types,omitempty"`
+}
+
+type StixIdentity struct {
+	stixCommon
+	Name          string `json:"name"`
+	IdentityClass string `json:"identity_class"`
+}
+
+type StixRelationship struct {
+	stixCommon
+	RelationshipType string `json:"relationship_type"`
+	SourceRef        string `json:"source_ref"`
+	TargetRef        string `json:"target_ref"`
+}
+
+// ToSTIX 把一条已生成的攻击链渲染为 STIX 2.1 bundle。
+// 每个节点会被拒绝导出，如果其 TechniqueID 没有通过内置 ATT&CK 映射表的校验。
+func ToSTIX(chain *attackchain.Chain, now time.Time) (*StixBundle, error) {
+	ts := now.UTC().Format("2006-01-02T15:04:05.000Z")
+	bundle := &StixBundle{
+		Type: "bundle",
+		ID:   "bundle--" + uuid.NewString(),
+	}
+
+	// node.ID -> attack-pattern SDO 的 STIX ID，用于后续生成 relationship
+	nodeStixID := make(map[string]string, len(chain.Nodes))
+
+	for _, node := range chain.Nodes {
+		if node.TechniqueID == "" {
+			continue
+		}
+		tech, err := attackchain.ValidateTechnique(node.TechniqueID)
+		if err != nil {
+			return nil, fmt.Errorf("节点 %s 导出失败: %w", node.ID, err)
+		}
+
+		id := "attack-pattern--" + uuid.NewString()
+		nodeStixID[node.ID] = id
+		bundle.Objects = append(bundle.Objects, StixAttackPattern{
+			stixCommon:  stixCommon{Type: "attack-pattern", ID: id, Created: ts, Modified: ts},
+			Name:        tech.Name,
+			Description: node.Description,
+			ExternalReferences: []StixExternalRef{{
+				SourceName: "mitre-attack",
+				ExternalID: tech.ID,
+				URL:        "https://attack.mitre.org/techniques/" + mitreTechniqueURLPath(tech.ID),
+			}},
+			KillChainPhases: []StixKillChainPhase{{
+				KillChainName: "mitre-attack",
+				PhaseName:     tech.Tactic,
+			}},
+		})
+
+		if node.Tool != "" {
+			toolID := "tool--" + uuid.NewString()
+			bundle.Objects = append(bundle.Objects, StixTool{
+				stixCommon: stixCommon{Type: "tool", ID: toolID, Created: ts, Modified: ts},
+				Name:       node.Tool,
+				ToolTypes:  []string{"security-tool"},
+			})
+			bundle.Objects = append(bundle.Objects, StixRelationship{
+				stixCommon:       stixCommon{Type: "relationship", ID: "relationship--" + uuid.NewString(), Created: ts, Modified: ts},
+				RelationshipType: "uses",
+				SourceRef:        id,
+				TargetRef:        toolID,
+			})
+		}
+
+		if node.Target != "" {
+			assetID := "infrastructure--" + uuid.NewString()
+			bundle.Objects = append(bundle.Objects, StixInfrastructure{
+				stixCommon:          stixCommon{Type: "infrastructure", ID: assetID, Created: ts, Modified: ts},
+				Name:                node.Target,
+				InfrastructureTypes: []string{"unknown"},
+			})
+			bundle.Objects = append(bundle.Objects, StixRelationship{
+				stixCommon:       stixCommon{Type: "relationship", ID: "relationship--" + uuid.NewString(), Created: ts, Modified: ts},
+				RelationshipType: "targets",
+				SourceRef:        id,
+				TargetRef:        assetID,
+			})
+		}
+	}
+
+	for _, edge := range chain.Edges {
+		from, ok1 := nodeStixID[edge.From]
+		to, ok2 := nodeStixID[edge.To]
+		if !ok1 || !ok2 {
+			continue
+		}
+		bundle.Objects = append(bundle.Objects, StixRelationship{
+			stixCommon:       stixCommon{Type: "relationship", ID: "relationship--" + uuid.NewString(), Created: ts, Modified: ts},
+			RelationshipType: "follows",
+			SourceRef:        from,
+			TargetRef:        to,
+		})
+	}
+
+	return bundle, nil
+}
+
+// mitreTechniqueURLPath 把 T1595.001 形式的 ID 转成 attack.mitre.org/techniques/ 下的 URL 路径片段（T1595/001）
+func mitreTechniqueURLPath(id string) string {
+	for i, r := range id {
+		if r == '.' {
+			return id[:i] + "/" + id[i+1:]
+		}
+	}
+	return id
+}
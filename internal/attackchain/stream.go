@@ -0,0 +1,167 @@
+package attackchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"cyberstrike-ai/internal/operations"
+
+	openai "github.com/sashabaranov/go-openai"
+	"go.uber.org/zap"
+)
+
+// EventType 流式构建攻击链时推送的事件类型
+type EventType string
+
+const (
+	EventStage     EventType = "stage"      // 阶段切换
+	EventNodeAdded EventType = "node_added" // 新增节点（局部结果）
+	EventToken     EventType = "token"      // LLM 流式输出的增量 token
+	EventDone      EventType = "done"       // 构建完成，携带最终攻击链
+	EventError     EventType = "error"      // 构建失败
+)
+
+// 构建阶段标识，与前端进度展示一一对应
+const (
+	StageLoadingConversation = "loading_conversation"
+	StageExtractingActions   = "extracting_actions"
+	StageLLMCall             = "llm_call"
+	StageLinkingNodes        = "linking_nodes"
+	StagePersisting          = "persisting"
+)
+
+// Event 攻击链流式构建过程中的单个事件
+type Event struct {
+	Type  EventType  `json:"type"`
+	Stage string     `json:"stage,omitempty"`
+	Node  *ChainNode `json:"node,omitempty"`
+	Token string     `json:"token,omitempty"`
+	Chain *Chain     `json:"chain,omitempty"`
+	Error string     `json:"error,omitempty"`
+}
+
+// BuildChainFromConversationStream 流式构建攻击链，在关键节点通过 events 通道推送进度
+// 调用方负责消费 events 直至其被关闭；通道在方法返回前始终会被关闭（含失败路径）
+func (b *Builder) BuildChainFromConversationStream(ctx context.Context, conversationID string, events chan<- Event) error {
+	defer close(events)
+
+	send := func(ev Event) error {
+		select {
+		case events <- ev:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	fail := func(stage string, err error) error {
+		_ = send(Event{Type: EventError, Stage: stage, Error: err.Error()})
+		return err
+	}
+
+	if err := send(Event{Type: EventStage, Stage: StageLoadingConversation}); err != nil {
+		return err
+	}
+	conv, err := b.db.GetConversation(conversationID)
+	if err != nil {
+		return fail(StageLoadingConversation, fmt.Errorf("加载对话失败: %w", err))
+	}
+
+	if err := send(Event{Type: EventStage, Stage: StageExtractingActions}); err != nil {
+		return err
+	}
+	actions, err := b.extractActions(conv)
+	if err != nil {
+		return fail(StageExtractingActions, fmt.Errorf("提取操作失败: %w", err))
+	}
+
+	if err := send(Event{Type: EventStage, Stage: StageLLMCall}); err != nil {
+		return err
+	}
+	nodes, err := b.streamClassifyActions(ctx, actions,
+		func(token string) error { return send(Event{Type: EventToken, Token: token}) },
+		func(node ChainNode) error { return send(Event{Type: EventNodeAdded, Node: &node}) },
+	)
+	if err != nil {
+		return fail(StageLLMCall, fmt.Errorf("LLM 分类失败: %w", err))
+	}
+
+	if err := send(Event{Type: EventStage, Stage: StageLinkingNodes}); err != nil {
+		return err
+	}
+	chain := b.linkNodes(conversationID, nodes)
+
+	if err := send(Event{Type: EventStage, Stage: StagePersisting}); err != nil {
+		return err
+	}
+	if err := b.persistChain(conversationID, chain); err != nil {
+		return fail(StagePersisting, fmt.Errorf("持久化攻击链失败: %w", err))
+	}
+
+	b.logger.Info("流式生成攻击链完成", zap.String("conversationId", conversationID), zap.Int("nodes", len(chain.Nodes)))
+	return send(Event{Type: EventDone, Chain: chain})
+}
+
+// streamClassifyActions 使用 OpenAI 流式接口对提取出的操作逐条分类，
+// onToken 在每个增量 token 到达时回调，onNode 在一个节点分类完成时回调
+func (b *Builder) streamClassifyActions(ctx context.Context, actions []ExtractedAction, onToken func(string) error, onNode func(ChainNode) error) ([]ChainNode, error) {
+	client := b.newOpenAIClient()
+	nodes := make([]ChainNode, 0, len(actions))
+
+	for i, action := range actions {
+		req := openai.ChatCompletionRequest{
+			Model:    b.openAIConfig.Model,
+			Messages: b.classificationPrompt(action),
+			Stream:   true,
+		}
+
+		requestStart := time.Now()
+		stream, err := client.CreateChatCompletionStream(ctx, req)
+		if err != nil {
+			operations.OpenAIRequestDuration.WithLabelValues(req.Model).Observe(time.Since(requestStart).Seconds())
+			return nil, fmt.Errorf("创建流式请求失败: %w", err)
+		}
+
+		var content string
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					stream.Close()
+					operations.OpenAIRequestDuration.WithLabelValues(req.Model).Observe(time.Since(requestStart).Seconds())
+					return nil, fmt.Errorf("读取流式响应失败: %w", err)
+				}
+				break
+			}
+			if len(resp.Choices) == 0 {
+				continue
+			}
+			delta := resp.Choices[0].Delta.Content
+			if delta == "" {
+				continue
+			}
+			content += delta
+			if err := onToken(delta); err != nil {
+				stream.Close()
+				operations.OpenAIRequestDuration.WithLabelValues(req.Model).Observe(time.Since(requestStart).Seconds())
+				return nil, err
+			}
+		}
+		stream.Close()
+		operations.OpenAIRequestDuration.WithLabelValues(req.Model).Observe(time.Since(requestStart).Seconds())
+
+		node, err := b.parseClassification(action, content)
+		if err != nil {
+			return nil, fmt.Errorf("解析第 %d 条操作分类结果失败: %w", i, err)
+		}
+		if err := onNode(node); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	return nodes, nil
+}
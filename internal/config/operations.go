@@ -0,0 +1,25 @@
+package config
+
+// OperationsConfig 对应 config.yaml 中的 operations: 段，控制独立运维监听器
+// （/metrics、/healthz、/readyz、/debug/pprof）的监听地址、TLS 与认证
+type OperationsConfig struct {
+	Enabled bool                    `yaml:"enabled"`
+	Host    string                  `yaml:"host"`
+	Port    int                     `yaml:"port"`
+	TLS     OperationsTLSConfig     `yaml:"tls"`
+	Auth    OperationsAuthConfig    `yaml:"auth"`
+}
+
+// OperationsTLSConfig 运维监听器的 TLS 设置，留空则以明文 HTTP 提供服务
+type OperationsTLSConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+// OperationsAuthConfig 运维监听器的基本认证设置；未启用时任何人都可以访问
+type OperationsAuthConfig struct {
+	Enabled  bool   `yaml:"enabled"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
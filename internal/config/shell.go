@@ -0,0 +1,10 @@
+package config
+
+import "time"
+
+// ShellSessionLimitsConfig 对应 config.yaml 中 agent.shell_session_limits 段，
+// 约束交互式 WebShell 会话（见 internal/webshell）的存活时间
+type ShellSessionLimitsConfig struct {
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+	MaxDuration time.Duration `yaml:"max_duration"`
+}
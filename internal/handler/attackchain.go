@@ -9,6 +9,7 @@ import (
 	"cyberstrike-ai/internal/attackchain"
 	"cyberstrike-ai/internal/config"
 	"cyberstrike-ai/internal/database"
+	"cyberstrike-ai/internal/operations"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -20,8 +21,8 @@ type AttackChainHandler struct {
 	logger       *zap.Logger
 	openAIConfig *config.OpenAIConfig
 	mu           sync.RWMutex // 保护 openAIConfig 的并发访问
-	// 用于防止同一对话的并发生成
-	generatingLocks sync.Map // map[string]*sync.Mutex
+	// 用于防止同一对话的并发生成，同时承担 SSE 订阅者的广播（见 attackchain_stream.go）
+	generatingLocks sync.Map // map[string]*chainBroker
 }
 
 // NewAttackChainHandler 创建新的攻击链处理器
@@ -81,8 +82,8 @@ func (h *AttackChainHandler) GetAttackChain(c *gin.Context) {
 
 	// 如果不存在，则生成新的攻击链（按需生成）
 	// 使用锁机制防止同一对话的并发生成
-	lockInterface, _ := h.generatingLocks.LoadOrStore(conversationID, &sync.Mutex{})
-	lock := lockInterface.(*sync.Mutex)
+	lockInterface, _ := h.generatingLocks.LoadOrStore(conversationID, &chainBroker{})
+	lock := lockInterface.(*chainBroker)
 	
 	// 尝试获取锁，如果正在生成则返回错误
 	acquired := lock.TryLock()
@@ -92,6 +93,15 @@ func (h *AttackChainHandler) GetAttackChain(c *gin.Context) {
 		return
 	}
 	defer lock.Unlock()
+	// 无论通过哪条路径返回，都要把结果广播给可能正挂在 GetAttackChainStream 上、
+	// piggy-back 到这同一个 broker 的 SSE 订阅者，否则它们会一直等不到任何事件
+	defer func() {
+		if err != nil {
+			lock.finish(attackchain.Event{Type: attackchain.EventError, Error: err.Error()})
+		} else if chain != nil {
+			lock.finish(attackchain.Event{Type: attackchain.EventDone, Chain: chain})
+		}
+	}()
 
 	// 再次检查是否已生成（可能在等待锁的过程中已经生成完成）
 	chain, err = builder.LoadChainFromDatabase(conversationID)
@@ -106,12 +116,18 @@ func (h *AttackChainHandler) GetAttackChain(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
+	operations.ActiveGenerationLocks.Inc()
+	defer operations.ActiveGenerationLocks.Dec()
+
+	start := time.Now()
 	chain, err = builder.BuildChainFromConversation(ctx, conversationID)
 	if err != nil {
+		operations.AttackChainBuildDuration.WithLabelValues(buildOutcome(ctx, err)).Observe(time.Since(start).Seconds())
 		h.logger.Error("生成攻击链失败", zap.String("conversationId", conversationID), zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成攻击链失败: " + err.Error()})
 		return
 	}
+	operations.AttackChainBuildDuration.WithLabelValues("success").Observe(time.Since(start).Seconds())
 
 	// 生成完成后，从锁映射中删除（可选，保留也可以用于防止短时间内重复生成）
 	// h.generatingLocks.Delete(conversationID)
@@ -119,6 +135,17 @@ func (h *AttackChainHandler) GetAttackChain(c *gin.Context) {
 	c.JSON(http.StatusOK, chain)
 }
 
+// buildOutcome 把构建错误归类为 Prometheus 指标的 outcome 标签值
+func buildOutcome(ctx context.Context, err error) string {
+	if ctx.Err() == context.DeadlineExceeded {
+		return "timeout"
+	}
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
 // RegenerateAttackChain 重新生成攻击链
 // POST /api/attack-chain/:conversationId/regenerate
 func (h *AttackChainHandler) RegenerateAttackChain(c *gin.Context) {
@@ -142,8 +169,8 @@ func (h *AttackChainHandler) RegenerateAttackChain(c *gin.Context) {
 	}
 
 	// 使用锁机制防止并发生成
-	lockInterface, _ := h.generatingLocks.LoadOrStore(conversationID, &sync.Mutex{})
-	lock := lockInterface.(*sync.Mutex)
+	lockInterface, _ := h.generatingLocks.LoadOrStore(conversationID, &chainBroker{})
+	lock := lockInterface.(*chainBroker)
 	
 	acquired := lock.TryLock()
 	if !acquired {
@@ -153,20 +180,36 @@ func (h *AttackChainHandler) RegenerateAttackChain(c *gin.Context) {
 	}
 	defer lock.Unlock()
 
+	var chain *attackchain.Chain
+	// 同样把结果广播给挂在这个 broker 上的 SSE 订阅者（见 GetAttackChain 中的说明）
+	defer func() {
+		if err != nil {
+			lock.finish(attackchain.Event{Type: attackchain.EventError, Error: err.Error()})
+		} else if chain != nil {
+			lock.finish(attackchain.Event{Type: attackchain.EventDone, Chain: chain})
+		}
+	}()
+
 	// 生成新的攻击链
 	h.logger.Info("重新生成攻击链", zap.String("conversationId", conversationID))
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
+	operations.ActiveGenerationLocks.Inc()
+	defer operations.ActiveGenerationLocks.Dec()
+
 	openAIConfig := h.getOpenAIConfig()
 	builder := attackchain.NewBuilder(h.db, openAIConfig, h.logger)
-	chain, err := builder.BuildChainFromConversation(ctx, conversationID)
+	start := time.Now()
+	chain, err = builder.BuildChainFromConversation(ctx, conversationID)
 	if err != nil {
+		operations.AttackChainBuildDuration.WithLabelValues(buildOutcome(ctx, err)).Observe(time.Since(start).Seconds())
 		h.logger.Error("生成攻击链失败", zap.String("conversationId", conversationID), zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成攻击链失败: " + err.Error()})
 		return
 	}
+	operations.AttackChainBuildDuration.WithLabelValues("success").Observe(time.Since(start).Seconds())
 
 	c.JSON(http.StatusOK, chain)
 }
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+	"time"
+
+	"cyberstrike-ai/internal/attackchain"
+	"cyberstrike-ai/internal/attackchain/export"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ExportAttackChain 把已生成的攻击链渲染为安全行业通用格式
+// GET /api/attack-chain/:conversationId/export?format=stix|attack-navigator|mermaid|graphml
+func (h *AttackChainHandler) ExportAttackChain(c *gin.Context) {
+	conversationID := c.Param("conversationId")
+	if conversationID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "conversationId is required"})
+		return
+	}
+
+	format := c.DefaultQuery("format", "stix")
+
+	openAIConfig := h.getOpenAIConfig()
+	builder := attackchain.NewBuilder(h.db, openAIConfig, h.logger)
+	chain, err := builder.LoadChainFromDatabase(conversationID)
+	if err != nil || len(chain.Nodes) == 0 {
+		h.logger.Warn("导出失败：攻击链不存在", zap.String("conversationId", conversationID), zap.Error(err))
+		c.JSON(http.StatusNotFound, gin.H{"error": "攻击链不存在，请先生成"})
+		return
+	}
+
+	switch format {
+	case "stix":
+		bundle, err := export.ToSTIX(chain, time.Now())
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "导出 STIX 失败: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, bundle)
+
+	case "attack-navigator":
+		layer, err := export.ToNavigatorLayer(chain, "CyberStrikeAI - "+conversationID)
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "导出 ATT&CK Navigator 层失败: " + err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, layer)
+
+	case "mermaid":
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(export.ToMermaid(chain)))
+
+	case "graphml":
+		doc, err := export.ToGraphML(chain)
+		if err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "导出 GraphML 失败: " + err.Error()})
+			return
+		}
+		out, err := xml.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "导出 GraphML 失败: " + err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "application/xml", append([]byte(xml.Header), out...))
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "不支持的导出格式: " + format})
+	}
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"cyberstrike-ai/internal/attackchain"
+	"cyberstrike-ai/internal/operations"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// chainBroker 既充当同一对话并发生成的互斥锁（TryLock/Unlock 语义与原有
+// generatingLocks 一致），又在持有锁期间把构建过程中的事件广播给所有订阅者，
+// 使同一对话的多个 SSE 连接共享同一次生成结果，而不是互相 409
+type chainBroker struct {
+	sync.Mutex
+
+	subMu       sync.Mutex
+	subscribers map[chan attackchain.Event]struct{}
+}
+
+// subscribe 注册一个订阅者，返回其专属事件通道
+func (b *chainBroker) subscribe() chan attackchain.Event {
+	ch := make(chan attackchain.Event, 16)
+	b.subMu.Lock()
+	if b.subscribers == nil {
+		b.subscribers = make(map[chan attackchain.Event]struct{})
+	}
+	b.subscribers[ch] = struct{}{}
+	b.subMu.Unlock()
+	return ch
+}
+
+// unsubscribe 注销一个订阅者并关闭其通道
+func (b *chainBroker) unsubscribe(ch chan attackchain.Event) {
+	b.subMu.Lock()
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+	b.subMu.Unlock()
+}
+
+// broadcast 把事件分发给当前所有订阅者；订阅者通道已满时跳过，避免单个慢客户端拖慢构建
+func (b *chainBroker) broadcast(ev attackchain.Event) {
+	b.subMu.Lock()
+	defer b.subMu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// closeAll 在构建结束后关闭并清空所有订阅者通道
+func (b *chainBroker) closeAll() {
+	b.subMu.Lock()
+	defer b.subMu.Unlock()
+	for ch := range b.subscribers {
+		close(ch)
+	}
+	b.subscribers = make(map[chan attackchain.Event]struct{})
+}
+
+// finish 广播一个终止事件（done/error）并立即关闭所有订阅者通道。
+// 调用方必须保证 ev 已经是这次构建的最终结果：broadcast 与 closeAll 在这里是
+// 同一个 goroutine 里顺序执行的，不存在 runChainBuild 里那种"广播协程还没发完、
+// closeAll 就先跑了"的竞争（见 runChainBuild 的注释）。
+func (b *chainBroker) finish(ev attackchain.Event) {
+	b.broadcast(ev)
+	b.closeAll()
+}
+
+// GetAttackChainStream 以 SSE 的形式推送攻击链生成进度
+// GET /api/attack-chain/:conversationId/stream
+func (h *AttackChainHandler) GetAttackChainStream(c *gin.Context) {
+	conversationID := c.Param("conversationId")
+	if conversationID == "" {
+		c.JSON(400, gin.H{"error": "conversationId is required"})
+		return
+	}
+
+	if _, err := h.db.GetConversation(conversationID); err != nil {
+		h.logger.Warn("对话不存在", zap.String("conversationId", conversationID), zap.Error(err))
+		c.JSON(404, gin.H{"error": "对话不存在"})
+		return
+	}
+
+	openAIConfig := h.getOpenAIConfig()
+	builder := attackchain.NewBuilder(h.db, openAIConfig, h.logger)
+
+	// 已经生成过，直接以单个 done 事件回放，无需走构建流程
+	if chain, err := builder.LoadChainFromDatabase(conversationID); err == nil && len(chain.Nodes) > 0 {
+		h.logger.Info("攻击链已存在，直接回放", zap.String("conversationId", conversationID))
+		h.streamEvents(c, singleEventChannel(attackchain.Event{Type: attackchain.EventDone, Chain: chain}))
+		return
+	}
+
+	lockInterface, _ := h.generatingLocks.LoadOrStore(conversationID, &chainBroker{})
+	broker := lockInterface.(*chainBroker)
+
+	sub := broker.subscribe()
+	defer broker.unsubscribe(sub)
+
+	if broker.TryLock() {
+		// 本次请求是第一个订阅者，负责真正发起构建；其余订阅者只消费广播
+		go h.runChainBuild(conversationID, builder, broker)
+	} else {
+		h.logger.Info("攻击链正在生成中，加入现有 SSE 广播", zap.String("conversationId", conversationID))
+	}
+
+	h.streamEvents(c, sub)
+}
+
+// runChainBuild 持有 broker 锁发起一次流式构建，并把事件广播给所有订阅者
+func (h *AttackChainHandler) runChainBuild(conversationID string, builder *attackchain.Builder, broker *chainBroker) {
+	defer broker.Unlock()
+
+	operations.ActiveGenerationLocks.Inc()
+	defer operations.ActiveGenerationLocks.Dec()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	events := make(chan attackchain.Event)
+	// drained 在广播协程消费完 events 里的最后一个元素（包括终止性的 done/error
+	// 事件）之后才关闭。BuildChainFromConversationStream 返回只代表它写完了、
+	// 不代表 broadcast 已经跑完，如果不等这个信号就直接 closeAll，会和还没来得及
+	// broadcast 的最后一个事件产生竞争，谁先抢到 subMu 谁赢——closeAll 赢的话，
+	// 最终结果就被无声丢弃，订阅者只会看到通道关闭、拿不到任何数据。
+	drained := make(chan struct{})
+	go func() {
+		defer close(drained)
+		for ev := range events {
+			broker.broadcast(ev)
+		}
+	}()
+
+	h.logger.Info("开始流式生成攻击链", zap.String("conversationId", conversationID))
+	start := time.Now()
+	err := builder.BuildChainFromConversationStream(ctx, conversationID, events)
+	operations.AttackChainBuildDuration.WithLabelValues(buildOutcome(ctx, err)).Observe(time.Since(start).Seconds())
+	if err != nil {
+		h.logger.Error("流式生成攻击链失败", zap.String("conversationId", conversationID), zap.Error(err))
+	}
+
+	<-drained
+	broker.closeAll()
+}
+
+// streamEvents 把单个订阅者通道的事件以 SSE 格式写回响应，直到通道关闭或客户端断开
+func (h *AttackChainHandler) streamEvents(c *gin.Context, ch <-chan attackchain.Event) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	clientGone := c.Writer.CloseNotify()
+	c.Stream(func(_ io.Writer) bool {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(ev.Type), ev)
+			return ev.Type != attackchain.EventDone && ev.Type != attackchain.EventError
+		case <-clientGone:
+			return false
+		}
+	})
+}
+
+// singleEventChannel 包装单个事件为一个已关闭的只读通道，便于复用 streamEvents
+func singleEventChannel(ev attackchain.Event) <-chan attackchain.Event {
+	ch := make(chan attackchain.Event, 1)
+	ch <- ev
+	close(ch)
+	return ch
+}
@@ -6,9 +6,11 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"time"
 
 	"cyberstrike-ai/internal/config"
 	"cyberstrike-ai/internal/mcp"
+	"cyberstrike-ai/internal/operations"
 	"cyberstrike-ai/internal/security"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -24,8 +26,19 @@ type ConfigHandler struct {
 	agent      AgentUpdater // Agent接口，用于更新Agent配置
 	logger     *zap.Logger
 	mu         sync.RWMutex
+	apiPort    int // API server 监听端口，供校验 MCP 端口冲突使用，见 SetAPIPort
+
+	// 最近一次成功探测过的 OpenAI 配置，命中且未过期时跳过重复的网络探测，见 validateOpenAI
+	validatedMu        sync.Mutex
+	validatedOpenAI    config.OpenAIConfig
+	hasValidatedOpenAI bool
+	validatedAt        time.Time
 }
 
+// openAIProbeTTL 是跳过重复探测的最长有效期：配置没变不代表 provider/key 一直健康，
+// 过了这个时间窗就应该再探测一次，而不是无限期信任第一次成功的结果
+const openAIProbeTTL = 5 * time.Minute
+
 // AgentUpdater Agent更新接口
 type AgentUpdater interface {
 	UpdateConfig(cfg *config.OpenAIConfig)
@@ -112,55 +125,67 @@ func (h *ConfigHandler) UpdateConfig(c *gin.Context) {
 		return
 	}
 
-	h.mu.Lock()
-	defer h.mu.Unlock()
+	// 先在一份副本上应用改动并完成校验（可能触发一次最长 10s 的 OpenAI 探测），
+	// 全程不持有 h.mu：GetConfig 的 RLock 不应该被这次探测卡住。只有校验通过、
+	// 真正要提交改动时才取写锁。
+	h.mu.RLock()
+	candidate := *h.config
+	candidate.Security.Tools = append(candidate.Security.Tools[:0:0], h.config.Security.Tools...)
+	h.mu.RUnlock()
 
-	// 更新OpenAI配置
 	if req.OpenAI != nil {
-		h.config.OpenAI = *req.OpenAI
+		candidate.OpenAI = *req.OpenAI
 		h.logger.Info("更新OpenAI配置",
-			zap.String("base_url", h.config.OpenAI.BaseURL),
-			zap.String("model", h.config.OpenAI.Model),
+			zap.String("base_url", candidate.OpenAI.BaseURL),
+			zap.String("model", candidate.OpenAI.Model),
 		)
 	}
 
-	// 更新MCP配置
 	if req.MCP != nil {
-		h.config.MCP = *req.MCP
+		candidate.MCP = *req.MCP
 		h.logger.Info("更新MCP配置",
-			zap.Bool("enabled", h.config.MCP.Enabled),
-			zap.String("host", h.config.MCP.Host),
-			zap.Int("port", h.config.MCP.Port),
+			zap.Bool("enabled", candidate.MCP.Enabled),
+			zap.String("host", candidate.MCP.Host),
+			zap.Int("port", candidate.MCP.Port),
 		)
 	}
 
-	// 更新Agent配置
 	if req.Agent != nil {
-		h.config.Agent = *req.Agent
+		candidate.Agent = *req.Agent
 		h.logger.Info("更新Agent配置",
-			zap.Int("max_iterations", h.config.Agent.MaxIterations),
+			zap.Int("max_iterations", candidate.Agent.MaxIterations),
 		)
 	}
 
-	// 更新工具启用状态
 	if req.Tools != nil {
 		toolMap := make(map[string]bool)
 		for _, toolStatus := range req.Tools {
 			toolMap[toolStatus.Name] = toolStatus.Enabled
 		}
 
-		// 更新配置中的工具状态
-		for i := range h.config.Security.Tools {
-			if enabled, ok := toolMap[h.config.Security.Tools[i].Name]; ok {
-				h.config.Security.Tools[i].Enabled = enabled
+		for i := range candidate.Security.Tools {
+			if enabled, ok := toolMap[candidate.Security.Tools[i].Name]; ok {
+				candidate.Security.Tools[i].Enabled = enabled
 				h.logger.Info("更新工具启用状态",
-					zap.String("tool", h.config.Security.Tools[i].Name),
+					zap.String("tool", candidate.Security.Tools[i].Name),
 					zap.Bool("enabled", enabled),
 				)
 			}
 		}
 	}
 
+	// 持久化前先跑一遍校验，拒绝明显会导致进程起不来或工具集失效的配置
+	if err := h.validateConfig(&candidate); err != nil {
+		h.logger.Warn("配置校验未通过，拒绝保存", zap.Error(err))
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "配置校验失败: " + err.Error()})
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.config = &candidate
+
 	// 保存配置到文件
 	if err := h.saveConfig(); err != nil {
 		h.logger.Error("保存配置失败", zap.Error(err))
@@ -173,17 +198,31 @@ func (h *ConfigHandler) UpdateConfig(c *gin.Context) {
 
 // ApplyConfig 应用配置（重新加载并重启相关服务）
 func (h *ConfigHandler) ApplyConfig(c *gin.Context) {
+	h.mu.RLock()
+	cfg := h.config
+	h.mu.RUnlock()
+
+	// 校验（可能触发一次最长 10s 的 OpenAI 探测）放在写锁之外，避免这段时间里
+	// GetConfig 的 RLock 也被卡住
+	if err := h.validateConfig(cfg); err != nil {
+		h.logger.Error("配置校验未通过，拒绝应用", zap.Error(err))
+		operations.ConfigApplyTotal.WithLabelValues("rejected").Inc()
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "配置校验失败: " + err.Error()})
+		return
+	}
+
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	// 重新注册工具（根据新的启用状态）
-	h.logger.Info("重新注册工具")
-	
-	// 清空MCP服务器中的工具
-	h.mcpServer.ClearTools()
-	
-	// 重新注册工具
-	h.executor.RegisterTools(h.mcpServer)
+	// 两阶段切换：先在内存中构建好一整套新的 MCP server + 工具集，
+	// 只有在其完全就绪之后才整体替换旧实例，避免 ClearTools 之后、
+	// RegisterTools 完成之前这段时间里进程处于工具集不完整的中间状态
+	newMCPServer := mcp.NewServer(h.logger)
+	newExecutor := security.NewExecutor(&h.config.Security, h.logger)
+	newExecutor.RegisterTools(newMCPServer)
+
+	h.mcpServer = newMCPServer
+	h.executor = newExecutor
 
 	// 更新Agent的OpenAI配置
 	if h.agent != nil {
@@ -192,12 +231,13 @@ func (h *ConfigHandler) ApplyConfig(c *gin.Context) {
 		h.logger.Info("Agent配置已更新")
 	}
 
-	h.logger.Info("配置已应用",
+	h.logger.Info("配置已应用（两阶段切换）",
 		zap.Int("tools_count", len(h.config.Security.Tools)),
 	)
+	operations.ConfigApplyTotal.WithLabelValues("success").Inc()
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "配置已应用",
+		"message":     "配置已应用",
 		"tools_count": len(h.config.Security.Tools),
 	})
 }
@@ -310,10 +350,10 @@ func (h *ConfigHandler) saveConfig() error {
 		return fmt.Errorf("序列化配置失败: %w", err)
 	}
 
-	// 创建备份
-	backupPath := h.configPath + ".backup"
-	if err := os.WriteFile(backupPath, data, 0644); err != nil {
-		h.logger.Warn("创建配置备份失败", zap.Error(err))
+	// 把即将被替换的配置存档为一个历史版本，而不是覆盖唯一的 .backup 文件，
+	// 这样连续两次保存也不会丢失最后一份好的配置
+	if _, err := h.commitVersion(data); err != nil {
+		h.logger.Warn("存档配置历史版本失败", zap.Error(err))
 	}
 
 	// 保存新配置
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"cyberstrike-ai/internal/config"
+	"cyberstrike-ai/internal/operations"
+
+	openai "github.com/sashabaranov/go-openai"
+	"gopkg.in/yaml.v3"
+)
+
+// toolSchema 是工具 YAML 配置允许出现的字段集合；yaml.v3 的 KnownFields 会在
+// 遇到未声明字段时报错，用来在应用前拒绝手写错误的工具配置文件
+type toolSchema struct {
+	Name             string   `yaml:"name"`
+	Description      string   `yaml:"description"`
+	ShortDescription string   `yaml:"short_description"`
+	Enabled          bool     `yaml:"enabled"`
+	Command          string   `yaml:"command"`
+	Args             []string `yaml:"args"`
+	TimeoutSeconds   int      `yaml:"timeout_seconds"`
+	Allowlist        []string `yaml:"allowlist"`
+}
+
+// SetAPIPort 告知 ConfigHandler 当前 API server 监听的端口，用于校验 MCP 端口不与其冲突
+func (h *ConfigHandler) SetAPIPort(port int) {
+	h.apiPort = port
+}
+
+// validateConfig 在配置被持久化或应用之前跑一遍校验，任何一项失败都会整体拒绝：
+//  1. OpenAI base URL 可达，且模型能响应一次最小探测请求
+//  2. tools_dir 下每个工具 YAML 都能按 schema 严格解析，未知字段直接拒绝
+//  3. agent.max_iterations > 0，且 MCP 端口不与 API 端口冲突
+func (h *ConfigHandler) validateConfig(cfg *config.Config) error {
+	if err := h.validateOpenAI(cfg.OpenAI); err != nil {
+		return fmt.Errorf("OpenAI 配置校验失败: %w", err)
+	}
+	if err := h.validateToolFiles(cfg); err != nil {
+		return fmt.Errorf("工具配置校验失败: %w", err)
+	}
+	if cfg.Agent.MaxIterations <= 0 {
+		return fmt.Errorf("agent.max_iterations 必须大于 0，当前为 %d", cfg.Agent.MaxIterations)
+	}
+	if h.apiPort > 0 && cfg.MCP.Enabled && cfg.MCP.Port == h.apiPort {
+		return fmt.Errorf("mcp.port (%d) 与 API 端口冲突", cfg.MCP.Port)
+	}
+	return nil
+}
+
+// validateOpenAI 探测 OpenAI base URL 是否可达，并发起一次最小的 chat completion 请求验证模型可用。
+// 真正的网络探测只在 cfg 相对上一次成功探测发生变化、或那次探测已经过了 openAIProbeTTL 时才
+// 执行一次，避免编辑一个与 OpenAI 无关的字段（比如切换某个工具的 enabled）也要白白等上一次
+// 探测的耗时，同时保证 provider/key 在探测窗口之外出问题时，后续保存/应用仍会重新发现。
+func (h *ConfigHandler) validateOpenAI(cfg config.OpenAIConfig) error {
+	if cfg.BaseURL == "" {
+		return fmt.Errorf("base_url 不能为空")
+	}
+	if cfg.Model == "" {
+		return fmt.Errorf("model 不能为空")
+	}
+
+	h.validatedMu.Lock()
+	alreadyValidated := h.hasValidatedOpenAI && h.validatedOpenAI == cfg && time.Since(h.validatedAt) < openAIProbeTTL
+	h.validatedMu.Unlock()
+	if alreadyValidated {
+		return nil
+	}
+
+	clientConfig := openai.DefaultConfig(cfg.APIKey)
+	clientConfig.BaseURL = cfg.BaseURL
+	client := openai.NewClientWithConfig(clientConfig)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	probeStart := time.Now()
+	resp, err := client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:     cfg.Model,
+		Messages:  []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "ping"}},
+		MaxTokens: 1,
+	})
+	operations.OpenAIRequestDuration.WithLabelValues(cfg.Model).Observe(time.Since(probeStart).Seconds())
+	if err != nil {
+		return fmt.Errorf("探测请求失败: %w", err)
+	}
+	operations.OpenAITokensTotal.WithLabelValues(cfg.Model, "prompt").Add(float64(resp.Usage.PromptTokens))
+	operations.OpenAITokensTotal.WithLabelValues(cfg.Model, "completion").Add(float64(resp.Usage.CompletionTokens))
+
+	h.validatedMu.Lock()
+	h.validatedOpenAI = cfg
+	h.hasValidatedOpenAI = true
+	h.validatedAt = time.Now()
+	h.validatedMu.Unlock()
+
+	return nil
+}
+
+// validateToolFiles 严格解析 tools_dir 下每个已声明工具的 YAML 文件，拒绝未知字段
+func (h *ConfigHandler) validateToolFiles(cfg *config.Config) error {
+	if cfg.Security.ToolsDir == "" {
+		return nil
+	}
+
+	configDir := filepath.Dir(h.configPath)
+	toolsDir := cfg.Security.ToolsDir
+	if !filepath.IsAbs(toolsDir) {
+		toolsDir = filepath.Join(configDir, toolsDir)
+	}
+
+	for _, tool := range cfg.Security.Tools {
+		path := filepath.Join(toolsDir, tool.Name+".yaml")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			path = filepath.Join(toolsDir, tool.Name+".yml")
+			data, err = os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("工具 %s 的配置文件不存在", tool.Name)
+			}
+		}
+
+		var schema toolSchema
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&schema); err != nil {
+			return fmt.Errorf("工具 %s 的配置文件包含未知字段或格式错误: %w", tool.Name, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"cyberstrike-ai/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigVersion 是 configs/history/ 下一个历史版本文件的元信息
+type ConfigVersion struct {
+	ID        string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	SHA256    string    `json:"sha256"`
+}
+
+// historyDir 历史版本的落盘目录，与主配置文件同级
+func (h *ConfigHandler) historyDir() string {
+	return filepath.Join(filepath.Dir(h.configPath), "configs", "history")
+}
+
+// commitVersion 把一份配置内容写入 configs/history/<timestamp>-<sha256>.yaml，
+// 文件名自带时间戳与内容哈希，同一内容重复提交也只会产生同一个文件，
+// 彻底取代过去那个每次保存都会被覆盖的单一 .backup 文件
+func (h *ConfigHandler) commitVersion(data []byte) (*ConfigVersion, error) {
+	dir := h.historyDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建配置历史目录失败: %w", err)
+	}
+
+	sum := sha256.Sum256(data)
+	sha := hex.EncodeToString(sum[:])
+	ts := time.Now().UTC()
+	id := fmt.Sprintf("%s-%s", ts.Format("20060102T150405Z"), sha)
+
+	// 内容完全相同的版本已存在（不论提交时间），直接复用，无需重复写入
+	if matches, _ := filepath.Glob(filepath.Join(dir, "*-"+sha+".yaml")); len(matches) > 0 {
+		if v, err := parseVersionID(strings.TrimSuffix(filepath.Base(matches[0]), ".yaml")); err == nil {
+			return &v, nil
+		}
+	}
+
+	path := filepath.Join(dir, id+".yaml")
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return nil, fmt.Errorf("写入配置历史版本失败: %w", err)
+	}
+
+	h.logger.Info("已记录配置历史版本", zap.String("id", id))
+	return &ConfigVersion{ID: id, Timestamp: ts, SHA256: sha}, nil
+}
+
+// listVersions 按时间倒序列出所有历史版本
+func (h *ConfigHandler) listVersions() ([]ConfigVersion, error) {
+	entries, err := os.ReadDir(h.historyDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取配置历史目录失败: %w", err)
+	}
+
+	versions := make([]ConfigVersion, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".yaml") {
+			continue
+		}
+		v, err := parseVersionID(strings.TrimSuffix(entry.Name(), ".yaml"))
+		if err != nil {
+			h.logger.Warn("忽略无法解析的配置历史文件", zap.String("name", entry.Name()), zap.Error(err))
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Timestamp.After(versions[j].Timestamp) })
+	return versions, nil
+}
+
+// parseVersionID 把 "<timestamp>-<sha256>" 形式的文件名解析为 ConfigVersion
+func parseVersionID(id string) (ConfigVersion, error) {
+	parts := strings.SplitN(id, "-", 2)
+	if len(parts) != 2 {
+		return ConfigVersion{}, fmt.Errorf("非法的版本 ID: %s", id)
+	}
+	ts, err := time.Parse("20060102T150405Z", parts[0])
+	if err != nil {
+		return ConfigVersion{}, fmt.Errorf("解析版本时间戳失败: %w", err)
+	}
+	return ConfigVersion{ID: id, Timestamp: ts, SHA256: parts[1]}, nil
+}
+
+// readVersion 读取某个历史版本的原始 YAML 内容
+func (h *ConfigHandler) readVersion(id string) ([]byte, error) {
+	if strings.ContainsAny(id, "/\\") {
+		return nil, fmt.Errorf("非法的版本 ID: %s", id)
+	}
+	path := filepath.Join(h.historyDir(), id+".yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置历史版本失败: %w", err)
+	}
+	return data, nil
+}
+
+// GetConfigVersions 列出所有配置历史版本
+// GET /api/config/versions
+func (h *ConfigHandler) GetConfigVersions(c *gin.Context) {
+	versions, err := h.listVersions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
+}
+
+// GetConfigVersion 返回某个历史版本与当前配置文件之间的逐行 diff
+// GET /api/config/versions/:id
+func (h *ConfigHandler) GetConfigVersion(c *gin.Context) {
+	id := c.Param("id")
+	historical, err := h.readVersion(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	current, err := os.ReadFile(h.configPath)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取当前配置失败: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":   id,
+		"diff": diffLines(string(historical), string(current)),
+	})
+}
+
+// RollbackConfig 把配置文件回滚到指定的历史版本（回滚前会先把当前配置存档，以便可以再次撤销）
+// POST /api/config/rollback/:id
+func (h *ConfigHandler) RollbackConfig(c *gin.Context) {
+	id := c.Param("id")
+
+	target, err := h.readVersion(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	var candidate config.Config
+	if err := yaml.Unmarshal(target, &candidate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "历史版本内容无法解析: " + err.Error()})
+		return
+	}
+
+	// 校验（可能触发一次最长 10s 的 OpenAI 探测）放在写锁之外：回滚本来就是在
+	// OpenAI 出问题时兜底用的，不能让这次探测本身反过来把 GetConfig 也一起卡住
+	if err := h.validateConfig(&candidate); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "历史版本未通过校验: " + err.Error()})
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// 回滚前把当前配置存档，确保回滚本身也是可撤销的
+	current, err := os.ReadFile(h.configPath)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取当前配置失败: " + err.Error()})
+		return
+	}
+	if _, err := h.commitVersion(current); err != nil {
+		h.logger.Warn("回滚前存档当前配置失败", zap.Error(err))
+	}
+
+	if err := os.WriteFile(h.configPath, target, 0644); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "写入配置文件失败: " + err.Error()})
+		return
+	}
+	h.config = &candidate
+
+	h.logger.Info("配置已回滚", zap.String("id", id))
+	c.JSON(http.StatusOK, gin.H{"message": "配置已回滚", "id": id})
+}
+
+// diffLines 是一个最小的逐行 diff 实现（基于最长公共子序列），
+// 足够用于在 UI 上展示历史版本与当前配置之间的差异，不追求 unified diff 的完整格式
+func diffLines(a, b string) []map[string]string {
+	linesA := strings.Split(a, "\n")
+	linesB := strings.Split(b, "\n")
+
+	n, m := len(linesA), len(linesB)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if linesA[i] == linesB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	diff := make([]map[string]string, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case linesA[i] == linesB[j]:
+			diff = append(diff, map[string]string{"op": "=", "line": linesA[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, map[string]string{"op": "-", "line": linesA[i]})
+			i++
+		default:
+			diff = append(diff, map[string]string{"op": "+", "line": linesB[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		diff = append(diff, map[string]string{"op": "-", "line": linesA[i]})
+	}
+	for ; j < m; j++ {
+		diff = append(diff, map[string]string{"op": "+", "line": linesB[j]})
+	}
+	return diff
+}
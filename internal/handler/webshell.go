@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"cyberstrike-ai/internal/config"
+	"cyberstrike-ai/internal/database"
+	"cyberstrike-ai/internal/operations"
+	"cyberstrike-ai/internal/security"
+	"cyberstrike-ai/internal/webshell"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// WebShellHandler 承载 /api/tools/:name/shell 与 /api/tools/sessions/:id/replay，
+// 让安全工程师可以从 Agent 建议的命令直接切换到交互式终端
+type WebShellHandler struct {
+	executor      *security.Executor
+	db            *database.DB
+	logger        *zap.Logger
+	recordingsDir string
+	limits        config.ShellSessionLimitsConfig
+	upgrader      websocket.Upgrader
+
+	sessions sync.Map // map[string]*webshell.Session
+}
+
+// NewWebShellHandler 创建 WebShellHandler；recordingsDir 用于存放 asciicast v2 录像文件
+func NewWebShellHandler(executor *security.Executor, db *database.DB, recordingsDir string, limits config.ShellSessionLimitsConfig, logger *zap.Logger) *WebShellHandler {
+	return &WebShellHandler{
+		executor:      executor,
+		db:            db,
+		logger:        logger,
+		recordingsDir: recordingsDir,
+		limits:        limits,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  4096,
+			WriteBufferSize: 4096,
+			// 不校验 Origin：任何来源的 WebSocket 升级请求都会被接受
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// Shell 把 /api/tools/:name/shell 升级为 WebSocket，并 PTY-attach 到对应的安全工具进程
+// GET /api/tools/:name/shell?conversationId=...
+func (h *WebShellHandler) Shell(c *gin.Context) {
+	toolName := c.Param("name")
+	conversationID := c.Query("conversationId")
+
+	tool, enabled := h.executor.ToolConfig(toolName)
+	if !enabled {
+		h.logger.Warn("拒绝打开交互式终端：工具未启用或不在白名单中", zap.String("tool", toolName))
+		c.JSON(http.StatusForbidden, gin.H{"error": "工具未启用或不允许交互式会话: " + toolName})
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		h.logger.Error("升级 WebSocket 失败", zap.String("tool", toolName), zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	sessionID := fmt.Sprintf("%s-%d", toolName, time.Now().UnixNano())
+	if err := os.MkdirAll(h.recordingsDir, 0755); err != nil {
+		h.logger.Error("创建录像目录失败", zap.Error(err))
+		return
+	}
+	recordPath := filepath.Join(h.recordingsDir, sessionID+".cast")
+
+	cmd := exec.Command(tool.Command, tool.Args...)
+	session, err := webshell.NewSession(sessionID, toolName, cmd, 80, 24, webshell.Limits{
+		IdleTimeout: h.limits.IdleTimeout,
+		MaxDuration: h.limits.MaxDuration,
+	}, recordPath)
+	if err != nil {
+		h.logger.Error("创建交互式会话失败", zap.String("tool", toolName), zap.Error(err))
+		_ = conn.WriteMessage(websocket.TextMessage, []byte("启动失败: "+err.Error()))
+		return
+	}
+	h.sessions.Store(sessionID, session)
+	defer h.sessions.Delete(sessionID)
+
+	h.logger.Info("交互式终端会话已建立", zap.String("tool", toolName), zap.String("sessionId", sessionID))
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		h.pumpOutput(conn, session)
+	}()
+	go func() {
+		defer wg.Done()
+		h.pumpInput(conn, session)
+	}()
+	wg.Wait()
+
+	_ = session.Close()
+	operations.ObserveToolInvocation(toolName, session.ExitCode())
+	h.tagInteractiveSession(conversationID, sessionID, toolName)
+}
+
+// pumpOutput 把 PTY 输出以二进制帧转发给客户端，直到会话结束
+func (h *WebShellHandler) pumpOutput(conn *websocket.Conn, session *webshell.Session) {
+	buf := make([]byte, 4096)
+	for {
+		n, err := session.Read(buf)
+		if n > 0 {
+			if writeErr := conn.WriteMessage(websocket.BinaryMessage, buf[:n]); writeErr != nil {
+				return
+			}
+		}
+		if err != nil {
+			return
+		}
+		select {
+		case <-session.Done():
+			return
+		default:
+		}
+	}
+}
+
+// pumpInput 把客户端发来的按键转发给 PTY，并处理 resize 控制消息
+func (h *WebShellHandler) pumpInput(conn *websocket.Conn, session *webshell.Session) {
+	for {
+		msgType, data, err := conn.ReadMessage()
+		if err != nil {
+			_ = session.Close()
+			return
+		}
+
+		if msgType == websocket.TextMessage {
+			var resize webshell.ResizeMessage
+			if err := json.Unmarshal(data, &resize); err == nil && resize.Type == "resize" {
+				if err := session.Resize(resize.Cols, resize.Rows); err != nil {
+					h.logger.Warn("调整终端大小失败", zap.Error(err))
+				}
+				continue
+			}
+		}
+
+		if _, err := session.Write(data); err != nil {
+			return
+		}
+	}
+}
+
+// tagInteractiveSession 把本次交互式会话记录为对话中的一条 interactive_session 消息，
+// 消息内容只有工具名、会话 ID 与录像回放路径；会话的输入输出内容不写入消息
+func (h *WebShellHandler) tagInteractiveSession(conversationID, sessionID, toolName string) {
+	if conversationID == "" {
+		return
+	}
+	content := fmt.Sprintf("交互式终端会话结束: tool=%s session=%s replay=/api/tools/sessions/%s/replay", toolName, sessionID, sessionID)
+	if err := h.db.AddMessage(conversationID, "interactive_session", content); err != nil {
+		h.logger.Warn("记录交互式会话到对话失败", zap.String("conversationId", conversationID), zap.Error(err))
+	}
+}
+
+// ReplaySession 返回某次交互式会话的 asciicast v2 录像，供前端播放器或 asciinema 回放
+// GET /api/tools/sessions/:id/replay
+func (h *WebShellHandler) ReplaySession(c *gin.Context) {
+	sessionID := c.Param("id")
+	if sessionID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "session id is required"})
+		return
+	}
+	if strings.ContainsAny(sessionID, "/\\") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "非法的 session id: " + sessionID})
+		return
+	}
+
+	path := filepath.Join(h.recordingsDir, sessionID+".cast")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "录像不存在: " + sessionID})
+		return
+	}
+
+	c.FileAttachment(path, sessionID+".cast")
+}
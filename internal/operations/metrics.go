@@ -0,0 +1,67 @@
+package operations
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 以下指标在各子系统内按需上报，统一在此处定义以避免重复注册 collector
+var (
+	// AttackChainBuildDuration 记录单次攻击链构建耗时，按结果（success/error/timeout）区分
+	AttackChainBuildDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cyberstrike_attackchain_build_duration_seconds",
+		Help:    "攻击链构建耗时（秒），按结果分类",
+		Buckets: prometheus.ExponentialBuckets(0.5, 2, 12), // 0.5s ~ ~17min
+	}, []string{"outcome"})
+
+	// ToolInvocations 记录工具调用次数；由交互式 WebShell 会话结束时上报，
+	// security.Executor 执行的工具调用不计入该指标
+	ToolInvocations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cyberstrike_tool_invocations_total",
+		Help: "安全工具调用次数，按工具名称与退出码分类",
+	}, []string{"tool", "exit_code"})
+
+	// OpenAIRequestDuration 记录 OpenAI 请求的耗时，按模型区分；由流式攻击链分类
+	// （attackchain.streamClassifyActions）与配置校验探测上报，其他 OpenAI 调用不计入
+	OpenAIRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cyberstrike_openai_request_duration_seconds",
+		Help:    "OpenAI 请求耗时（秒），按模型分类",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"model"})
+
+	// OpenAITokensTotal 记录 OpenAI 的 token 用量，按模型与 token 类型（prompt/completion）区分；
+	// 只有配置校验探测上报，流式攻击链分类的 token 用量不计入
+	OpenAITokensTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cyberstrike_openai_tokens_total",
+		Help: "OpenAI token 用量，按模型与类型分类",
+	}, []string{"model", "token_type"})
+
+	// ActiveGenerationLocks 当前正在进行的攻击链生成数量
+	ActiveGenerationLocks = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "cyberstrike_attackchain_active_generations",
+		Help: "当前正在进行中的攻击链生成数量",
+	})
+
+	// ConfigApplyTotal 记录 ApplyConfig 的调用结果
+	ConfigApplyTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cyberstrike_config_apply_total",
+		Help: "配置应用次数，按结果分类",
+	}, []string{"outcome"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		AttackChainBuildDuration,
+		ToolInvocations,
+		OpenAIRequestDuration,
+		OpenAITokensTotal,
+		ActiveGenerationLocks,
+		ConfigApplyTotal,
+	)
+}
+
+// ObserveToolInvocation 在一次工具调用结束后上报其工具名与退出码（调用方为 handler.WebShellHandler）
+func ObserveToolInvocation(tool string, exitCode int) {
+	ToolInvocations.WithLabelValues(tool, strconv.Itoa(exitCode)).Inc()
+}
@@ -0,0 +1,107 @@
+// Package operations 承载一个独立于业务 API 的运维监听器：
+// /metrics、/healthz、/readyz 与 /debug/pprof，配置来自 config.yaml 的 operations: 段
+package operations
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+
+	"cyberstrike-ai/internal/config"
+
+	"go.uber.org/zap"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Ready 决定 /readyz 的返回状态：初始为未就绪（503），调用 Set(true) 后返回 200
+type Ready struct {
+	ready bool
+}
+
+func (r *Ready) Set(ready bool) { r.ready = ready }
+
+// Server 是运维监听器，与业务 API server 完全独立，互不影响彼此的生命周期
+type Server struct {
+	httpServer *http.Server
+	logger     *zap.Logger
+	ready      *Ready
+}
+
+// NewServer 创建运维监听器；cfg.Enabled 为 false 时仍返回一个可用的 Server，
+// 调用方可以统一走 Start/Shutdown 流程而不必额外判空
+func NewServer(cfg config.OperationsConfig, logger *zap.Logger) *Server {
+	ready := &Ready{}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !ready.ready {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("not ready"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ready"))
+	})
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	var handler http.Handler = mux
+	if cfg.Auth.Enabled {
+		handler = basicAuthMiddleware(cfg.Auth, mux)
+	}
+
+	return &Server{
+		httpServer: &http.Server{
+			Addr:    fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+			Handler: handler,
+		},
+		logger: logger,
+		ready:  ready,
+	}
+}
+
+// Ready 返回该监听器的就绪状态，调用方通过它标记就绪
+func (s *Server) Ready() *Ready { return s.ready }
+
+// Start 启动运维监听器，阻塞直到出错或被 Shutdown 关闭
+func (s *Server) Start(cfg config.OperationsConfig) error {
+	if !cfg.Enabled {
+		s.logger.Info("运维监听器未启用，跳过启动")
+		return nil
+	}
+	s.logger.Info("启动运维监听器", zap.String("addr", s.httpServer.Addr), zap.Bool("tls", cfg.TLS.Enabled))
+	if cfg.TLS.Enabled {
+		return s.httpServer.ListenAndServeTLS(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	}
+	return s.httpServer.ListenAndServe()
+}
+
+// Shutdown 优雅关闭运维监听器
+func (s *Server) Shutdown(ctx context.Context) error {
+	return s.httpServer.Shutdown(ctx)
+}
+
+// basicAuthMiddleware 对运维端点加一层 HTTP Basic Auth，避免 /debug/pprof 等敏感端点裸奔
+func basicAuthMiddleware(cfg config.OperationsAuthConfig, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(user), []byte(cfg.Username)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(pass), []byte(cfg.Password)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Basic realm="cyberstrike-operations"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,76 @@
+package webshell
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Recorder 把一个 PTY 会话录制为 asciicast v2 格式（https://docs.asciinema.org/manual/asciicast/v2/），
+// 便于之后通过 /api/tools/sessions/:id/replay 或任意 asciinema 播放器回放
+type Recorder struct {
+	mu      sync.Mutex
+	file    *os.File
+	started time.Time
+}
+
+type asciicastHeader struct {
+	Version   int    `json:"version"`
+	Width     int    `json:"width"`
+	Height    int    `json:"height"`
+	Timestamp int64  `json:"timestamp"`
+	Command   string `json:"command,omitempty"`
+	Title     string `json:"title,omitempty"`
+}
+
+// NewRecorder 在 path 创建一个新的 asciicast v2 录像文件并写入头部
+func NewRecorder(path string, cols, rows int, command string, startedAt time.Time) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("创建录像文件失败: %w", err)
+	}
+
+	header := asciicastHeader{
+		Version:   2,
+		Width:     cols,
+		Height:    rows,
+		Timestamp: startedAt.Unix(),
+		Command:   command,
+		Title:     "CyberStrikeAI interactive shell",
+	}
+	headerLine, err := json.Marshal(header)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("序列化录像头部失败: %w", err)
+	}
+	if _, err := f.Write(append(headerLine, '\n')); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("写入录像头部失败: %w", err)
+	}
+
+	return &Recorder{file: f, started: startedAt}, nil
+}
+
+// WriteEvent 追加一条 asciicast 事件：[elapsed_seconds, "o"|"i", data]
+// "o" 表示 PTY 输出，"i" 表示用户输入
+func (r *Recorder) WriteEvent(stream string, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	elapsed := time.Since(r.started).Seconds()
+	line, err := json.Marshal([]interface{}{elapsed, stream, string(data)})
+	if err != nil {
+		return fmt.Errorf("序列化录像事件失败: %w", err)
+	}
+	_, err = r.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close 关闭录像文件
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
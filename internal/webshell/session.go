@@ -0,0 +1,174 @@
+// Package webshell 管理交互式 WebShell 会话：PTY 生命周期、空闲/最长时长限制与 asciicast 录像
+package webshell
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/creack/pty"
+)
+
+// ResizeMessage 是客户端通过 WebSocket 发来的终端尺寸调整消息
+type ResizeMessage struct {
+	Type string `json:"type"`
+	Cols int    `json:"cols"`
+	Rows int    `json:"rows"`
+}
+
+// Limits 控制一个会话允许存活多久
+type Limits struct {
+	IdleTimeout time.Duration
+	MaxDuration time.Duration
+}
+
+// Session 是一个 PTY-attach 的交互式会话
+type Session struct {
+	ID       string
+	ToolName string
+
+	cmd  *exec.Cmd
+	ptmx *os.File
+
+	recorder *Recorder
+	limits   Limits
+
+	startedAt    time.Time
+	lastActivity atomic.Int64 // unix nano，原子更新，供空闲超时判断使用
+
+	closeOnce sync.Once
+	done      chan struct{}
+	exitCode  int
+}
+
+// NewSession 启动 cmd 并通过 PTY 接管其标准输入输出，同时开始录像。
+// 调用方必须保证 cmd 对应的工具已经通过 security.Executor 的启用检查与白名单校验。
+func NewSession(id, toolName string, cmd *exec.Cmd, cols, rows int, limits Limits, recordPath string) (*Session, error) {
+	ptmx, err := pty.StartWithSize(cmd, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+	if err != nil {
+		return nil, fmt.Errorf("启动 PTY 失败: %w", err)
+	}
+
+	startedAt := time.Now()
+	recorder, err := NewRecorder(recordPath, cols, rows, cmd.String(), startedAt)
+	if err != nil {
+		_ = ptmx.Close()
+		_ = cmd.Process.Kill()
+		return nil, err
+	}
+
+	s := &Session{
+		ID:        id,
+		ToolName:  toolName,
+		cmd:       cmd,
+		ptmx:      ptmx,
+		recorder:  recorder,
+		limits:    limits,
+		startedAt: startedAt,
+		done:      make(chan struct{}),
+	}
+	s.touch()
+	go s.enforceLimits()
+	return s, nil
+}
+
+// touch 记录一次活跃时间，用于空闲超时判断
+func (s *Session) touch() {
+	s.lastActivity.Store(time.Now().UnixNano())
+}
+
+// Read 从 PTY 读取工具输出，同时写入录像
+func (s *Session) Read(p []byte) (int, error) {
+	n, err := s.ptmx.Read(p)
+	if n > 0 {
+		s.touch()
+		_ = s.recorder.WriteEvent("o", p[:n])
+	}
+	return n, err
+}
+
+// Write 把客户端键入的数据转发给 PTY，同时写入录像
+func (s *Session) Write(p []byte) (int, error) {
+	s.touch()
+	_ = s.recorder.WriteEvent("i", p)
+	return s.ptmx.Write(p)
+}
+
+// Resize 调整 PTY 窗口大小
+func (s *Session) Resize(cols, rows int) error {
+	s.touch()
+	return pty.Setsize(s.ptmx, &pty.Winsize{Cols: uint16(cols), Rows: uint16(rows)})
+}
+
+// Done 在 Close 执行后关闭（超出空闲/最长时长限制时会自动 Close，调用方也可以主动 Close）；
+// 工具进程自行退出本身不会关闭它，此时 Read 会返回错误
+func (s *Session) Done() <-chan struct{} { return s.done }
+
+// Close 终止工具进程、关闭 PTY 并落盘录像；可安全重复调用。
+// 会等待进程退出以回收其资源，退出码可通过 ExitCode 获取（供调用方上报指标）。
+func (s *Session) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		err = s.ptmx.Close()
+		if s.cmd.Process != nil {
+			_ = s.cmd.Process.Kill()
+		}
+		_ = s.cmd.Wait()
+		if s.cmd.ProcessState != nil {
+			s.exitCode = s.cmd.ProcessState.ExitCode()
+		}
+		_ = s.recorder.Close()
+		close(s.done)
+	})
+	return err
+}
+
+// ExitCode 返回会话对应进程的退出码；只有在 Close 返回后才有意义
+func (s *Session) ExitCode() int {
+	return s.exitCode
+}
+
+// enforceLimits 按 idle timeout 与 max duration 两个维度监控会话，超限则关闭。
+// 与 idle timeout 一样，MaxDuration <= 0 表示不限制：time.NewTimer(0) 会立刻触发，
+// 未配置 max_duration 的部署不应该一打开终端就被自己关掉。
+func (s *Session) enforceLimits() {
+	var maxTimerC <-chan time.Time
+	if s.limits.MaxDuration > 0 {
+		maxTimer := time.NewTimer(s.limits.MaxDuration)
+		defer maxTimer.Stop()
+		maxTimerC = maxTimer.C
+	}
+
+	idleTicker := time.NewTicker(pollInterval(s.limits.IdleTimeout))
+	defer idleTicker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-maxTimerC:
+			_ = s.Close()
+			return
+		case <-idleTicker.C:
+			idleSince := time.Since(time.Unix(0, s.lastActivity.Load()))
+			if s.limits.IdleTimeout > 0 && idleSince >= s.limits.IdleTimeout {
+				_ = s.Close()
+				return
+			}
+		}
+	}
+}
+
+// pollInterval 选择一个合理的空闲检测周期，避免 IdleTimeout 很短时忙轮询
+func pollInterval(idleTimeout time.Duration) time.Duration {
+	if idleTimeout <= 0 {
+		return time.Minute
+	}
+	if idleTimeout/4 < time.Second {
+		return time.Second
+	}
+	return idleTimeout / 4
+}